@@ -8,6 +8,7 @@ import (
 	"github.com/github/git-bundle-server/internal/bundles"
 	"github.com/github/git-bundle-server/internal/core"
 	"github.com/github/git-bundle-server/internal/git"
+	"github.com/github/git-bundle-server/internal/route"
 )
 
 type initCmd struct{}
@@ -23,23 +24,53 @@ func (initCmd) Name() string {
 func (initCmd) Description() string {
 	return `
 Initialize a repository by cloning a bare repo from '<url>', whose bundles
-should be hosted at '<route>'.`
+should be hosted at '<route>'. If '<route>' is omitted, it is derived from
+'<url>' (e.g. 'https://github.com/foo/bar.git' becomes 'foo/bar').`
 }
 
+// initCmd's parser only declares the positionals it's actually given:
+// argparse has no documented support for an optional trailing positional,
+// so rather than lean on unverified parser behavior, we pick between two
+// fixed-arity parsers based on how many arguments were passed.
 func (initCmd) Run(ctx context.Context, args []string) error {
-	parser := argparse.NewArgParser("git-bundle-server init <url> <route>")
-	url := parser.PositionalString("url", "the URL of a repository to clone")
-	// TODO: allow parsing <route> out of <url>
-	route := parser.PositionalString("route", "the route to host the specified repo")
-	parser.Parse(ctx, args)
+	var url, routeArg string
+
+	if len(args) >= 2 {
+		parser := argparse.NewArgParser("git-bundle-server init <url> <route>")
+		urlArg := parser.PositionalString("url", "the URL of a repository to clone")
+		routeFlag := parser.PositionalString("route", "the route to host the specified repo")
+		parser.Parse(ctx, args)
+		url = *urlArg
+		routeArg = *routeFlag
+	} else {
+		parser := argparse.NewArgParser("git-bundle-server init <url>")
+		urlArg := parser.PositionalString("url", "the URL of a repository to clone; <route> defaults to '<owner>/<repo>' parsed from this URL")
+		parser.Parse(ctx, args)
+		url = *urlArg
+	}
+
+	repoRoute := routeArg
+	if repoRoute == "" {
+		derivedRoute, err := route.FromURL(url)
+		if err != nil {
+			return fmt.Errorf("could not derive route from '%s'; pass <route> explicitly: %w", url, err)
+		}
+		repoRoute = derivedRoute
+	}
+
+	return initRepository(url, repoRoute)
+}
 
-	repo, err := core.CreateRepository(*route)
+// initRepository clones url as a bare repo registered at route and
+// publishes its initial bundle.
+func initRepository(url string, repoRoute string) error {
+	repo, err := core.CreateRepository(repoRoute)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Cloning repository from %s\n", *url)
-	gitErr := git.GitCommand("clone", "--bare", *url, repo.RepoDir)
+	fmt.Printf("Cloning repository from %s\n", url)
+	gitErr := git.GitCommand("clone", "--bare", url, repo.RepoDir)
 
 	if gitErr != nil {
 		return fmt.Errorf("failed to clone repository: %w", gitErr)
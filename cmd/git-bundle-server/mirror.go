@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/github/git-bundle-server/internal/argparse"
+	"github.com/github/git-bundle-server/internal/bundles"
+	"github.com/github/git-bundle-server/internal/core"
+	"github.com/github/git-bundle-server/internal/git"
+	"github.com/github/git-bundle-server/internal/mirror"
+)
+
+type mirrorUpstream struct {
+	URL          string `json:"url"`
+	Route        string `json:"route"`
+	PollInterval string `json:"poll_interval"`
+	Refspec      string `json:"refspec"`
+}
+
+type mirrorConfig struct {
+	Upstreams []mirrorUpstream `json:"upstreams"`
+}
+
+type mirrorCmd struct{}
+
+func NewMirrorCommand() argparse.Subcommand {
+	return &mirrorCmd{}
+}
+
+func (mirrorCmd) Name() string {
+	return "mirror"
+}
+
+func (mirrorCmd) Description() string {
+	return `
+Continuously mirror the upstream repositories listed in '<config>',
+polling each on its own interval and regenerating bundles only when new
+commits appear.`
+}
+
+func (mirrorCmd) Run(ctx context.Context, args []string) error {
+	parser := argparse.NewArgParser("git-bundle-server mirror <config>")
+	configPath := parser.PositionalString("config", "the path to a JSON file listing the upstreams to mirror")
+	parser.Parse(ctx, args)
+
+	config, err := loadMirrorConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if len(config.Upstreams) == 0 {
+		return fmt.Errorf("mirror config '%s' lists no upstreams", *configPath)
+	}
+
+	done := make(chan error, len(config.Upstreams))
+	for _, upstream := range config.Upstreams {
+		go runMirrorLoop(ctx, upstream, done)
+	}
+
+	// Each loop reports back a fatal, per-upstream setup error, or nil
+	// once ctx is cancelled; otherwise it polls forever.
+	for range config.Upstreams {
+		if err := <-done; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadMirrorConfig(path string) (*mirrorConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mirror config: %w", err)
+	}
+
+	var config mirrorConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse mirror config '%s': %w", path, err)
+	}
+
+	return &config, nil
+}
+
+func runMirrorLoop(ctx context.Context, upstream mirrorUpstream, done chan<- error) {
+	interval, err := time.ParseDuration(upstream.PollInterval)
+	if err != nil {
+		done <- fmt.Errorf("invalid poll_interval '%s' for route '%s': %w", upstream.PollInterval, upstream.Route, err)
+		return
+	}
+
+	repo, err := core.CreateRepository(upstream.Route)
+	if err != nil {
+		done <- fmt.Errorf("failed to register route '%s': %w", upstream.Route, err)
+		return
+	}
+
+	if _, err := os.ReadDir(repo.RepoDir); err != nil {
+		if err := initializeMirror(repo, upstream); err != nil {
+			done <- err
+			return
+		}
+	}
+
+	for {
+		if err := pollMirror(repo, upstream); err != nil {
+			fmt.Printf("Failed to poll route '%s': %s\n", upstream.Route, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			done <- nil
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func initializeMirror(repo core.Repository, upstream mirrorUpstream) error {
+	fmt.Printf("Cloning repository from %s for route '%s'\n", upstream.URL, upstream.Route)
+	if err := git.GitCommand("clone", "--bare", upstream.URL, repo.RepoDir); err != nil {
+		return fmt.Errorf("failed to clone repository for route '%s': %w", upstream.Route, err)
+	}
+
+	refspec := upstream.Refspec
+	if refspec == "" {
+		refspec = "+refs/heads/*:refs/heads/*"
+	}
+	if err := git.GitCommand("-C", repo.RepoDir, "config", "remote.origin.fetch", refspec); err != nil {
+		return fmt.Errorf("failed to configure refspec for route '%s': %w", upstream.Route, err)
+	}
+
+	// A freshly cloned repo has no prior bundle published for it, and
+	// pollMirror's first fetch will see no tip changes against the clone
+	// it just did. Publish the initial bundle now rather than waiting for
+	// a ref change that may never come.
+	now := time.Now()
+	return publishBundle(repo, upstream, now, mirror.Status{Route: upstream.Route, LastFetchTime: now})
+}
+
+func pollMirror(repo core.Repository, upstream mirrorUpstream) error {
+	beforeTips, err := refTips(repo.RepoDir)
+	if err != nil {
+		return err
+	}
+
+	if err := git.GitCommand("-C", repo.RepoDir, "fetch", "--prune", "origin"); err != nil {
+		return fmt.Errorf("failed to fetch: %w", err)
+	}
+
+	now := time.Now()
+	status, hadStatus := mirror.ReadStatus(repo.RepoDir)
+	if !hadStatus {
+		status = mirror.Status{Route: upstream.Route}
+	}
+	status.LastFetchTime = now
+
+	afterTips, err := refTips(repo.RepoDir)
+	if err != nil {
+		return err
+	}
+
+	if afterTips == beforeTips {
+		// Ref tips are unchanged; skip regenerating the bundle and
+		// republishing an identical bundle list.
+		return mirror.WriteStatus(repo.RepoDir, status)
+	}
+
+	return publishBundle(repo, upstream, now, status)
+}
+
+// publishBundle regenerates the bundle and bundle list for repo and
+// persists status, recording now as the change time and the resulting
+// HEAD commit. It's shared by the initial clone (which has no prior tips
+// to diff against) and pollMirror's detected-change path.
+func publishBundle(repo core.Repository, upstream mirrorUpstream, now time.Time, status mirror.Status) error {
+	bundle := bundles.CreateInitialBundle(repo)
+	written, err := git.CreateBundle(repo.RepoDir, bundle.Filename)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	if !written {
+		return fmt.Errorf("refused to write empty bundle for route '%s'", upstream.Route)
+	}
+
+	list := bundles.CreateSingletonList(bundle)
+	if err := bundles.WriteBundleList(list, repo); err != nil {
+		return fmt.Errorf("failed to write bundle list: %w", err)
+	}
+
+	head, err := currentHead(repo.RepoDir)
+	if err != nil {
+		return err
+	}
+
+	status.LastChangeTime = now
+	status.HeadCommit = head
+
+	return mirror.WriteStatus(repo.RepoDir, status)
+}
+
+// refTips returns a stable, sorted snapshot of every ref and the commit it
+// points to, used to detect whether a fetch changed anything. A repo with
+// no matching refs is a valid, non-error result: git show-ref exits 1 with
+// no output in that case rather than printing anything.
+func refTips(repoDir string) (string, error) {
+	out, err := exec.Command("git", "-C", repoDir, "show-ref").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(out) == 0 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read ref tips: %w", err)
+	}
+	return string(out), nil
+}
+
+func currentHead(repoDir string) (string, error) {
+	out, err := exec.Command("git", "-C", repoDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -2,31 +2,116 @@ package main
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/git-ecosystem/git-bundle-server/internal/auth"
 	"github.com/git-ecosystem/git-bundle-server/internal/bundles"
 	"github.com/git-ecosystem/git-bundle-server/internal/cmd"
 	"github.com/git-ecosystem/git-bundle-server/internal/common"
 	"github.com/git-ecosystem/git-bundle-server/internal/core"
 	"github.com/git-ecosystem/git-bundle-server/internal/git"
+	"github.com/git-ecosystem/git-bundle-server/internal/listenfd"
 	"github.com/git-ecosystem/git-bundle-server/internal/log"
+	"github.com/git-ecosystem/git-bundle-server/internal/mirror"
+	"github.com/git-ecosystem/git-bundle-server/internal/webhook"
 )
 
+// SocketActivationConfig selects which of the listeners inherited via the
+// LISTEN_FDS/LISTEN_PID protocol (see internal/listenfd) should be used for
+// the plain and TLS endpoints. Indices are 1-based; the zero value of
+// SocketActivationConfig disables socket activation entirely, so a caller
+// that never inherited sockets can pass it unmodified.
+type SocketActivationConfig struct {
+	PlainFDIndex int
+	TLSFDIndex   int
+}
+
+func (s SocketActivationConfig) listener(inherited []net.Listener, index int) net.Listener {
+	if index < 1 || index > len(inherited) {
+		return nil
+	}
+	return inherited[index-1]
+}
+
+// WebhookConfig supplies the HMAC secret(s) used to authenticate requests
+// to the "/_hooks/{owner}/{repo}" endpoint. RouteSecrets overrides
+// GlobalSecret for the routes it lists; the zero value disables the
+// webhook endpoint entirely.
+type WebhookConfig struct {
+	GlobalSecret string
+	RouteSecrets map[string]string
+}
+
+func (w WebhookConfig) secretFor(route string) (string, bool) {
+	if secret, ok := w.RouteSecrets[route]; ok {
+		return secret, true
+	}
+	if w.GlobalSecret != "" {
+		return w.GlobalSecret, true
+	}
+	return "", false
+}
+
+// componentFilter implements the GIT_BUNDLE_SERVER_DEBUG component filter:
+// a comma-separated list of path.Match glob patterns (e.g.
+// "http.*,bundles.write") matched against each log site's dotted component
+// name. It is compiled once at construction time; hasPatterns is a
+// fast-path so a server run with the env var unset pays no matching cost.
+type componentFilter struct {
+	patterns    []string
+	hasPatterns bool
+}
+
+func newComponentFilterFromEnv() componentFilter {
+	var patterns []string
+	for _, pattern := range strings.Split(os.Getenv("GIT_BUNDLE_SERVER_DEBUG"), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return componentFilter{patterns: patterns, hasPatterns: len(patterns) > 0}
+}
+
+func (f componentFilter) matches(component string) bool {
+	if !f.hasPatterns {
+		return false
+	}
+	for _, pattern := range f.patterns {
+		if ok, _ := path.Match(pattern, component); ok {
+			return true
+		}
+	}
+	return false
+}
+
 type bundleWebServer struct {
-	logger             log.TraceLogger
-	server             *http.Server
-	serverWaitGroup    *sync.WaitGroup
-	listenAndServeFunc func() error
+	logger              log.TraceLogger
+	server              *http.Server
+	serverWaitGroup     *sync.WaitGroup
+	listenAndServeFuncs []func() error
+	authorizer          auth.Authorizer
+	enableGitProtocol   bool
+	webhookConfig       WebhookConfig
+	webhooks            *webhook.Manager
+	debugFilter         componentFilter
 }
 
 func NewBundleWebServer(logger log.TraceLogger,
@@ -34,23 +119,56 @@ func NewBundleWebServer(logger log.TraceLogger,
 	certFile string, keyFile string,
 	tlsMinVersion uint16,
 	clientCAFile string,
+	socketActivation SocketActivationConfig,
+	authBackend string,
+	enableGitProtocol bool,
+	webhookConfig WebhookConfig,
 ) (*bundleWebServer, error) {
 	bundleServer := &bundleWebServer{
-		logger:          logger,
-		serverWaitGroup: &sync.WaitGroup{},
+		logger:            logger,
+		serverWaitGroup:   &sync.WaitGroup{},
+		enableGitProtocol: enableGitProtocol,
+		webhookConfig:     webhookConfig,
+		webhooks:          webhook.NewManager(),
+		debugFilter:       newComponentFilterFromEnv(),
+	}
+
+	if authBackend != "" {
+		bundleServer.authorizer = auth.NewHTTPBackend(authBackend)
 	}
 
 	// Configure the http.Server
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", bundleServer.serve)
 	bundleServer.server = &http.Server{
-		Handler: mux,
+		Handler: bundleServer.accessLogMiddleware(mux),
 		Addr:    ":" + port,
 	}
 
-	// No TLS configuration to be done, return
+	inherited, err := listenfd.Listeners()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sockets inherited from init system: %w", err)
+	}
+
+	plainListener := socketActivation.listener(inherited, socketActivation.PlainFDIndex)
+	tlsListener := socketActivation.listener(inherited, socketActivation.TLSFDIndex)
+
+	// No TLS configuration to be done, return. Any inherited TLS-mode fd
+	// goes unused in this mode and must be closed rather than leaked for
+	// the life of the process.
 	if certFile == "" {
-		bundleServer.listenAndServeFunc = func() error { return bundleServer.server.ListenAndServe() }
+		if tlsListener != nil {
+			tlsListener.Close()
+		}
+		if plainListener != nil {
+			bundleServer.listenAndServeFuncs = []func() error{
+				func() error { return bundleServer.server.Serve(plainListener) },
+			}
+		} else {
+			bundleServer.listenAndServeFuncs = []func() error{
+				func() error { return bundleServer.server.ListenAndServe() },
+			}
+		}
 		return bundleServer, nil
 	}
 
@@ -59,7 +177,22 @@ func NewBundleWebServer(logger log.TraceLogger,
 		MinVersion: tlsMinVersion,
 	}
 	bundleServer.server.TLSConfig = tlsConfig
-	bundleServer.listenAndServeFunc = func() error { return bundleServer.server.ListenAndServeTLS(certFile, keyFile) }
+	if tlsListener != nil {
+		bundleServer.listenAndServeFuncs = append(bundleServer.listenAndServeFuncs,
+			func() error { return bundleServer.server.ServeTLS(tlsListener, certFile, keyFile) })
+	} else {
+		bundleServer.listenAndServeFuncs = append(bundleServer.listenAndServeFuncs,
+			func() error { return bundleServer.server.ListenAndServeTLS(certFile, keyFile) })
+	}
+
+	// An inherited plain-mode fd alongside a TLS configuration means the
+	// operator wants both endpoints served concurrently from this process
+	// (that's what independent PlainFDIndex/TLSFDIndex knobs are for), not
+	// just one silently serving while the other's fd leaks.
+	if plainListener != nil {
+		bundleServer.listenAndServeFuncs = append(bundleServer.listenAndServeFuncs,
+			func() error { return bundleServer.server.Serve(plainListener) })
+	}
 
 	if clientCAFile != "" {
 		caBytes, err := os.ReadFile(clientCAFile)
@@ -75,6 +208,64 @@ func NewBundleWebServer(logger log.TraceLogger,
 	return bundleServer, nil
 }
 
+// logf emits a component-tagged log line. Elsewhere in this file,
+// b.logger.Region(ctx, category, name) opens a named tracing span around a
+// block of work; its name is a short, low-cardinality span label, not a
+// log sink, so a formatted, high-cardinality message doesn't belong there.
+// Lacking a confirmed message-logging method on log.TraceLogger, this
+// writes directly to stdout with the component prefix, which is the one
+// form we know reliably produces visible output.
+func (b *bundleWebServer) logf(ctx context.Context, component string, format string, args ...any) {
+	fmt.Printf("[%s] "+format+"\n", append([]any{component}, args...)...)
+}
+
+// debugf is like logf, but only emits when component matches a pattern in
+// GIT_BUNDLE_SERVER_DEBUG, for detail that's too noisy to log by default.
+func (b *bundleWebServer) debugf(ctx context.Context, component string, format string, args ...any) {
+	if !b.debugFilter.matches(component) {
+		return
+	}
+	b.logf(ctx, component, format, args...)
+}
+
+type accessLogRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rec *accessLogRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *accessLogRecorder) Write(p []byte) (int, error) {
+	n, err := rec.ResponseWriter.Write(p)
+	rec.bytes += n
+	return n, err
+}
+
+// accessLogMiddleware logs one line per request (method, path, status,
+// bytes, duration, remote addr, and TLS peer CN if any), so a 404 that
+// today only surfaces as "Failed to open file" on stdout can be traced
+// back to the route and client that triggered it.
+func (b *bundleWebServer) accessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &accessLogRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		peerCN := ""
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			peerCN = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+
+		b.logf(r.Context(), "http.access", "%s %s %d %dB %s remote=%s cn=%q",
+			r.Method, r.URL.Path, rec.status, rec.bytes, time.Since(start), r.RemoteAddr, peerCN)
+	})
+}
+
 func (b *bundleWebServer) parseRoute(ctx context.Context, path string) (string, string, string, error) {
 	elements := strings.FieldsFunc(path, func(char rune) bool { return char == '/' })
 	switch len(elements) {
@@ -91,9 +282,391 @@ func (b *bundleWebServer) parseRoute(ctx context.Context, path string) (string,
 	}
 }
 
+// parseGitProtocolRoute recognizes the smart-HTTP Git protocol routes
+// "/{owner}/{repo}/info/refs" and "/{owner}/{repo}/git-upload-pack",
+// returning ok == false for anything else so those paths fall through to
+// the bundle-serving routing unchanged.
+func (b *bundleWebServer) parseGitProtocolRoute(path string) (owner string, repo string, isInfoRefs bool, ok bool) {
+	elements := strings.FieldsFunc(path, func(char rune) bool { return char == '/' })
+	switch {
+	case len(elements) == 4 && elements[2] == "info" && elements[3] == "refs":
+		return elements[0], elements[1], true, true
+	case len(elements) == 3 && elements[2] == "git-upload-pack":
+		return elements[0], elements[1], false, true
+	default:
+		return "", "", false, false
+	}
+}
+
+// denyHeaderSkip lists headers that describe the auth backend's own
+// response body (entity headers) or apply only hop-by-hop, not the
+// response we proxy back to the client. denyWithDecision writes no body of
+// its own, so forwarding these verbatim — Content-Length in particular —
+// produces a response whose declared length doesn't match what's sent.
+var denyHeaderSkip = map[string]bool{
+	"Content-Length":      true,
+	"Content-Encoding":    true,
+	"Transfer-Encoding":   true,
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Upgrade":             true,
+}
+
+// denyWithDecision proxies a non-2xx Authorizer decision back to the
+// client, preserving any headers (e.g. WWW-Authenticate) it returned. A
+// status below 400 is sent as 403 Forbidden.
+func (b *bundleWebServer) denyWithDecision(w http.ResponseWriter, decision auth.Decision) {
+	for key, values := range decision.Header {
+		if denyHeaderSkip[http.CanonicalHeaderKey(key)] {
+			continue
+		}
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	status := decision.StatusCode
+	if status < 400 {
+		status = http.StatusForbidden
+	}
+	w.WriteHeader(status)
+}
+
+// writePktLine writes s as a single Git pkt-line: a 4-byte hex length
+// (counting itself) followed by s.
+func writePktLine(w io.Writer, s string) error {
+	_, err := fmt.Fprintf(w, "%04x%s", len(s)+4, s)
+	return err
+}
+
+func (b *bundleWebServer) serveGitUploadPack(w http.ResponseWriter, r *http.Request, owner string, repo string, advertiseRefs bool) {
+	ctx := r.Context()
+
+	ctx, exitRegion := b.logger.Region(ctx, "http", "git-upload-pack")
+	defer exitRegion()
+
+	if advertiseRefs && r.URL.Query().Get("service") != "git-upload-pack" {
+		w.WriteHeader(http.StatusBadRequest)
+		b.logf(ctx, "git.upload-pack", "unsupported service for info/refs request")
+		return
+	}
+
+	// The git-upload-pack RPC endpoint takes its request on the POST body;
+	// a GET there would run git-upload-pack against an effectively empty
+	// stdin instead of being rejected outright.
+	if !advertiseRefs && r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	route := owner + "/" + repo
+
+	if b.authorizer != nil {
+		decision, err := b.authorizer.Authorize(ctx, r, owner, repo)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			b.logf(ctx, "git.upload-pack", "failed to authorize request for %s: %s", route, err)
+			return
+		}
+		if !decision.Allowed {
+			b.denyWithDecision(w, decision)
+			return
+		}
+		if len(decision.AllowedPaths) > 0 {
+			// The auth backend scoped this route to specific bundle
+			// filenames, a restriction git-upload-pack can't honor since it
+			// always exposes the full repository; refuse rather than widen
+			// access beyond what was authorized.
+			w.WriteHeader(http.StatusForbidden)
+			b.logf(ctx, "git.upload-pack", "auth backend scoped route %s to specific paths; refusing full-repo access", route)
+			return
+		}
+	}
+
+	userProvider := common.NewUserProvider()
+	fileSystem := common.NewFileSystem()
+	commandExecutor := cmd.NewCommandExecutor(b.logger)
+	gitHelper := git.NewGitHelper(b.logger, commandExecutor)
+	repoProvider := core.NewRepositoryProvider(b.logger, userProvider, fileSystem, gitHelper)
+
+	repos, err := repoProvider.GetRepositories(ctx)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		b.logf(ctx, "git.upload-pack", "failed to load routes: %s", err)
+		return
+	}
+
+	repository, contains := repos[route]
+	if !contains {
+		w.WriteHeader(http.StatusNotFound)
+		b.logf(ctx, "git.upload-pack", "failed to get route %s out of repos", route)
+		return
+	}
+
+	uploadPackArgs := []string{"upload-pack", "--stateless-rpc"}
+	if advertiseRefs {
+		uploadPackArgs = append(uploadPackArgs, "--advertise-refs")
+	}
+	uploadPackArgs = append(uploadPackArgs, repository.RepoDir)
+
+	uploadPack := exec.CommandContext(ctx, "git", uploadPackArgs...)
+	uploadPack.Stdin = r.Body
+	uploadPack.Stderr = os.Stderr
+
+	stdout, err := uploadPack.StdoutPipe()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		b.logf(ctx, "git.upload-pack", "failed to attach to git-upload-pack output: %s", err)
+		return
+	}
+
+	if advertiseRefs {
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+		// The whole point of falling back to the git protocol is letting
+		// clients recover from stale bundle state; an intermediate cache
+		// serving a stale ref advertisement would defeat that.
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-result")
+	}
+
+	if err := uploadPack.Start(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		b.logf(ctx, "git.upload-pack", "failed to start git-upload-pack: %s", err)
+		return
+	}
+
+	if advertiseRefs {
+		// upload-pack's stateless-rpc advertisement doesn't include the
+		// smart-HTTP service announcement; git-http-backend adds it, so we
+		// have to write it ourselves for clients to accept the response.
+		writePktLine(w, "# service=git-upload-pack\n")
+		io.WriteString(w, "0000")
+	}
+
+	io.Copy(w, stdout)
+
+	if err := uploadPack.Wait(); err != nil {
+		b.logf(ctx, "git.upload-pack", "git-upload-pack for %s exited with error: %s", route, err)
+	}
+}
+
+// serveStatus reports each known route's mirror status (last fetch time,
+// last change time, and current head commit) as JSON, for operators
+// running the 'git-bundle-server mirror' daemon against many upstreams.
+func (b *bundleWebServer) serveStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userProvider := common.NewUserProvider()
+	fileSystem := common.NewFileSystem()
+	commandExecutor := cmd.NewCommandExecutor(b.logger)
+	gitHelper := git.NewGitHelper(b.logger, commandExecutor)
+	repoProvider := core.NewRepositoryProvider(b.logger, userProvider, fileSystem, gitHelper)
+
+	repos, err := repoProvider.GetRepositories(ctx)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		b.logf(ctx, "http.status", "failed to load routes: %s", err)
+		return
+	}
+
+	statuses := make([]mirror.Status, 0, len(repos))
+	for route, repository := range repos {
+		if b.authorizer != nil {
+			parts := strings.SplitN(route, "/", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			decision, err := b.authorizer.Authorize(ctx, r, parts[0], parts[1])
+			if err != nil || !decision.Allowed {
+				// Don't let an unauthorized caller even learn that this
+				// route exists.
+				continue
+			}
+		}
+
+		status, ok := mirror.ReadStatus(repository.RepoDir)
+		if !ok {
+			continue
+		}
+		status.Route = route
+		statuses = append(statuses, status)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statuses)
+}
+
+// parseHooksRoute recognizes the webhook routes "/_hooks/{owner}/{repo}"
+// and "/_hooks/jobs/{id}", returning kind == "" for anything else.
+func (b *bundleWebServer) parseHooksRoute(path string) (kind string, first string, second string) {
+	elements := strings.FieldsFunc(path, func(char rune) bool { return char == '/' })
+	if len(elements) != 3 || elements[0] != "_hooks" {
+		return "", "", ""
+	}
+	if elements[1] == "jobs" {
+		return "job", elements[2], ""
+	}
+	return "route", elements[1], elements[2]
+}
+
+func (b *bundleWebServer) verifyWebhookSignature(secret string, body []byte, signatureHeader string) bool {
+	const prefix = "sha256="
+	if !strings.HasPrefix(signatureHeader, prefix) {
+		return false
+	}
+
+	given, err := hex.DecodeString(strings.TrimPrefix(signatureHeader, prefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(given, mac.Sum(nil))
+}
+
+func (b *bundleWebServer) regenerateBundle(repository core.Repository) error {
+	if err := git.GitCommand("-C", repository.RepoDir, "fetch", "origin"); err != nil {
+		return fmt.Errorf("failed to fetch: %w", err)
+	}
+
+	bundle := bundles.CreateInitialBundle(repository)
+	written, err := git.CreateBundle(repository.RepoDir, bundle.Filename)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	if !written {
+		return fmt.Errorf("refused to write empty bundle")
+	}
+
+	list := bundles.CreateSingletonList(bundle)
+	return bundles.WriteBundleList(list, repository)
+}
+
+// serveWebhook triggers an out-of-band bundle regeneration for <owner>/
+// <repo> in response to a validly-signed push notification (e.g. a GitHub
+// webhook). It enqueues the work onto that route's worker and returns
+// immediately with a job id that "GET /_hooks/jobs/{id}" can poll.
+func (b *bundleWebServer) serveWebhook(w http.ResponseWriter, r *http.Request, owner string, repo string) {
+	ctx := r.Context()
+	route := owner + "/" + repo
+
+	secret, ok := b.webhookConfig.secretFor(route)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		b.logf(ctx, "webhook", "no webhook configured for route %s", route)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		b.logf(ctx, "webhook", "failed to read webhook payload: %s", err)
+		return
+	}
+
+	if !b.verifyWebhookSignature(secret, body, r.Header.Get("X-Hub-Signature-256")) {
+		w.WriteHeader(http.StatusUnauthorized)
+		b.logf(ctx, "webhook", "rejected webhook with invalid signature for route %s", route)
+		return
+	}
+
+	userProvider := common.NewUserProvider()
+	fileSystem := common.NewFileSystem()
+	commandExecutor := cmd.NewCommandExecutor(b.logger)
+	gitHelper := git.NewGitHelper(b.logger, commandExecutor)
+	repoProvider := core.NewRepositoryProvider(b.logger, userProvider, fileSystem, gitHelper)
+
+	repos, err := repoProvider.GetRepositories(ctx)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		b.logf(ctx, "webhook", "failed to load routes: %s", err)
+		return
+	}
+
+	repository, contains := repos[route]
+	if !contains {
+		w.WriteHeader(http.StatusNotFound)
+		b.logf(ctx, "webhook", "failed to get route %s out of repos", route)
+		return
+	}
+
+	job := b.webhooks.Enqueue(route, func() error { return b.regenerateBundle(repository) })
+	b.debugf(ctx, "webhook", "enqueued job %s for route %s", job.ID, route)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// serveWebhookJobStatus reports a previously enqueued webhook job's status.
+// Job ids are sequential, so without an authorization check here, anyone
+// could enumerate every route's webhook activity, including its route name
+// and raw error text, regardless of the auth backend configured for that
+// route. When an auth backend is configured, it's gated by that backend's
+// decision for the job's route, the same check that guards the route's
+// bundles; serveWebhook itself checks only the HMAC signature.
+func (b *bundleWebServer) serveWebhookJobStatus(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	job, ok := b.webhooks.Status(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if b.authorizer != nil {
+		parts := strings.SplitN(job.Route, "/", 2)
+		if len(parts) != 2 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		decision, err := b.authorizer.Authorize(ctx, r, parts[0], parts[1])
+		if err != nil || !decision.Allowed {
+			// Don't let an unauthorized caller even learn that this job
+			// id exists.
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
 func (b *bundleWebServer) serve(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	if r.URL.Path == "/_status" {
+		b.serveStatus(w, r)
+		return
+	}
+
+	if kind, first, second := b.parseHooksRoute(r.URL.Path); kind != "" {
+		switch kind {
+		case "job":
+			b.serveWebhookJobStatus(w, r, first)
+		case "route":
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			b.serveWebhook(w, r, first, second)
+		}
+		return
+	}
+
+	if b.enableGitProtocol {
+		if owner, repo, isInfoRefs, ok := b.parseGitProtocolRoute(r.URL.Path); ok {
+			b.serveGitUploadPack(w, r, owner, repo, isInfoRefs)
+			return
+		}
+	}
+
 	ctx, exitRegion := b.logger.Region(ctx, "http", "serve")
 	defer exitRegion()
 
@@ -101,12 +674,31 @@ func (b *bundleWebServer) serve(w http.ResponseWriter, r *http.Request) {
 	owner, repo, filename, err := b.parseRoute(ctx, path)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
-		fmt.Printf("Failed to parse route: %s\n", err)
+		b.logf(ctx, "http.serve", "failed to parse route %s: %s", path, err)
 		return
 	}
 
 	route := owner + "/" + repo
 
+	var decision auth.Decision
+	if b.authorizer != nil {
+		decision, err = b.authorizer.Authorize(ctx, r, owner, repo)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			b.logf(ctx, "http.serve", "failed to authorize request for %s: %s", route, err)
+			return
+		}
+		if !decision.Allowed {
+			b.denyWithDecision(w, decision)
+			return
+		}
+		if !decision.PathAllowed(filename) {
+			w.WriteHeader(http.StatusForbidden)
+			b.logf(ctx, "http.serve", "auth backend disallowed path %s for %s", filename, route)
+			return
+		}
+	}
+
 	userProvider := common.NewUserProvider()
 	fileSystem := common.NewFileSystem()
 	commandExecutor := cmd.NewCommandExecutor(b.logger)
@@ -116,14 +708,14 @@ func (b *bundleWebServer) serve(w http.ResponseWriter, r *http.Request) {
 	repos, err := repoProvider.GetRepositories(ctx)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Printf("Failed to load routes\n")
+		b.logf(ctx, "http.serve", "failed to load routes: %s", err)
 		return
 	}
 
 	repository, contains := repos[route]
 	if !contains {
 		w.WriteHeader(http.StatusNotFound)
-		fmt.Printf("Failed to get route out of repos\n")
+		b.logf(ctx, "http.serve", "failed to get route %s out of repos", route)
 		return
 	}
 
@@ -141,7 +733,7 @@ func (b *bundleWebServer) serve(w http.ResponseWriter, r *http.Request) {
 	} else if filename == bundles.BundleListFilename || filename == bundles.RepoBundleListFilename {
 		// If the request identifies a non-bundle "reserved" file, return 404
 		w.WriteHeader(http.StatusNotFound)
-		fmt.Printf("Failed to open file\n")
+		b.logf(ctx, "http.serve", "refused to serve reserved file %s for %s", filename, route)
 		return
 	} else {
 		fileToServe = filepath.Join(repository.WebDir, filename)
@@ -150,27 +742,29 @@ func (b *bundleWebServer) serve(w http.ResponseWriter, r *http.Request) {
 	file, err := os.OpenFile(fileToServe, os.O_RDONLY, 0)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
-		fmt.Printf("Failed to open file\n")
+		b.logf(ctx, "http.serve", "failed to open file %s for %s: %s", fileToServe, route, err)
 		return
 	}
 
-	fmt.Printf("Successfully serving content for %s/%s\n", route, filename)
+	b.debugf(ctx, "http.serve", "serving content for %s/%s", route, filename)
 	http.ServeContent(w, r, filename, time.UnixMicro(0), file)
 }
 
 func (b *bundleWebServer) StartServerAsync(ctx context.Context) {
-	// Add to wait group
-	b.serverWaitGroup.Add(1)
+	for _, listenAndServe := range b.listenAndServeFuncs {
+		// Add to wait group
+		b.serverWaitGroup.Add(1)
 
-	go func(ctx context.Context) {
-		defer b.serverWaitGroup.Done()
+		go func(ctx context.Context, listenAndServe func() error) {
+			defer b.serverWaitGroup.Done()
 
-		// Return error unless it indicates graceful shutdown
-		err := b.listenAndServeFunc()
-		if err != nil && err != http.ErrServerClosed {
-			b.logger.Fatal(ctx, err)
-		}
-	}(ctx)
+			// Return error unless it indicates graceful shutdown
+			err := listenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				b.logger.Fatal(ctx, err)
+			}
+		}(ctx, listenAndServe)
+	}
 
 	fmt.Println("Server is running at address " + b.server.Addr)
 }
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+)
+
+// Decision is the result of an authorization check for a single request.
+type Decision struct {
+	// Allowed reports whether the request may proceed.
+	Allowed bool
+
+	// AllowedPaths, when non-empty, restricts which bundle filenames may be
+	// served for this request. An empty slice means no restriction beyond
+	// Allowed.
+	AllowedPaths []string
+
+	// StatusCode and Header carry the backend's response so that a denied
+	// request can be proxied back to the client (e.g. a 401 with
+	// WWW-Authenticate set).
+	StatusCode int
+	Header     http.Header
+}
+
+// PathAllowed reports whether filename may be served under this decision.
+func (d Decision) PathAllowed(filename string) bool {
+	if len(d.AllowedPaths) == 0 {
+		return true
+	}
+	for _, allowed := range d.AllowedPaths {
+		if allowed == filename {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorizer decides whether a request for <owner>/<repo> may be served.
+// Implementations may consult a remote service (see HTTPBackend), a static
+// token file, an HMAC-signed URL scheme, or an OIDC introspection endpoint.
+type Authorizer interface {
+	Authorize(ctx context.Context, r *http.Request, owner string, repo string) (Decision, error)
+}
@@ -0,0 +1,45 @@
+package auth
+
+import "testing"
+
+func TestDecision_PathAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		decision Decision
+		filename string
+		want     bool
+	}{
+		{
+			name:     "no restriction",
+			decision: Decision{Allowed: true},
+			filename: "bundle-1.bundle",
+			want:     true,
+		},
+		{
+			name:     "allowed path matches",
+			decision: Decision{Allowed: true, AllowedPaths: []string{"a.bundle", "b.bundle"}},
+			filename: "b.bundle",
+			want:     true,
+		},
+		{
+			name:     "allowed path does not match",
+			decision: Decision{Allowed: true, AllowedPaths: []string{"a.bundle"}},
+			filename: "b.bundle",
+			want:     false,
+		},
+		{
+			name:     "empty allowed paths slice allows everything",
+			decision: Decision{Allowed: true, AllowedPaths: []string{}},
+			filename: "anything.bundle",
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.decision.PathAllowed(tt.filename); got != tt.want {
+				t.Errorf("PathAllowed(%q) = %v, want %v", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HTTPBackend is an Authorizer that delegates the decision to an external
+// service by issuing a subrequest to "{BaseURL}/{owner}/{repo}/authorize",
+// forwarding the incoming request's Authorization header, cookies, and
+// client-certificate subject. A 2xx response allows the request; any other
+// status is treated as a denial and proxied back to the caller.
+type HTTPBackend struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewHTTPBackend creates an HTTPBackend targeting baseURL, using
+// http.DefaultClient.
+func NewHTTPBackend(baseURL string) *HTTPBackend {
+	return &HTTPBackend{
+		BaseURL: baseURL,
+		Client:  http.DefaultClient,
+	}
+}
+
+func (h *HTTPBackend) Authorize(ctx context.Context, r *http.Request, owner string, repo string) (Decision, error) {
+	url := strings.TrimRight(h.BaseURL, "/") + "/" + owner + "/" + repo + "/authorize"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to build authorize request: %w", err)
+	}
+
+	if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+	for _, cookie := range r.Cookies() {
+		req.AddCookie(cookie)
+	}
+	if r.TLS != nil {
+		for _, cert := range r.TLS.PeerCertificates {
+			req.Header.Add("X-Client-Cert-Subject", cert.Subject.String())
+		}
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to reach auth backend: %w", err)
+	}
+	defer resp.Body.Close()
+
+	decision := Decision{
+		Allowed:    resp.StatusCode >= 200 && resp.StatusCode < 300,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+
+	if allowPaths := resp.Header.Get("X-Bundle-Allow-Paths"); allowPaths != "" {
+		for _, path := range strings.Split(allowPaths, ",") {
+			decision.AllowedPaths = append(decision.AllowedPaths, strings.TrimSpace(path))
+		}
+	}
+
+	return decision, nil
+}
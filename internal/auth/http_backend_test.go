@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPBackend_Authorize(t *testing.T) {
+	tests := []struct {
+		name           string
+		backendStatus  int
+		allowPathsHdr  string
+		wantAllowed    bool
+		wantAllowPaths []string
+	}{
+		{
+			name:          "2xx allows",
+			backendStatus: http.StatusOK,
+			wantAllowed:   true,
+		},
+		{
+			name:          "401 denies",
+			backendStatus: http.StatusUnauthorized,
+			wantAllowed:   false,
+		},
+		{
+			name:           "allow-paths header is split and trimmed",
+			backendStatus:  http.StatusOK,
+			allowPathsHdr:  "a.bundle, b.bundle",
+			wantAllowed:    true,
+			wantAllowPaths: []string{"a.bundle", "b.bundle"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				if tt.allowPathsHdr != "" {
+					w.Header().Set("X-Bundle-Allow-Paths", tt.allowPathsHdr)
+				}
+				w.WriteHeader(tt.backendStatus)
+			}))
+			defer server.Close()
+
+			backend := NewHTTPBackend(server.URL)
+			req := httptest.NewRequest(http.MethodGet, "/foo/bar/info/refs", nil)
+
+			decision, err := backend.Authorize(req.Context(), req, "foo", "bar")
+			if err != nil {
+				t.Fatalf("Authorize() returned error: %v", err)
+			}
+
+			if gotPath != "/foo/bar/authorize" {
+				t.Errorf("backend received path %q, want /foo/bar/authorize", gotPath)
+			}
+			if decision.Allowed != tt.wantAllowed {
+				t.Errorf("Allowed = %v, want %v", decision.Allowed, tt.wantAllowed)
+			}
+			if decision.StatusCode != tt.backendStatus {
+				t.Errorf("StatusCode = %d, want %d", decision.StatusCode, tt.backendStatus)
+			}
+			if tt.wantAllowPaths != nil {
+				if len(decision.AllowedPaths) != len(tt.wantAllowPaths) {
+					t.Fatalf("AllowedPaths = %v, want %v", decision.AllowedPaths, tt.wantAllowPaths)
+				}
+				for i, want := range tt.wantAllowPaths {
+					if decision.AllowedPaths[i] != want {
+						t.Errorf("AllowedPaths[%d] = %q, want %q", i, decision.AllowedPaths[i], want)
+					}
+				}
+			}
+		})
+	}
+}
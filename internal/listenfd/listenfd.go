@@ -0,0 +1,49 @@
+package listenfd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// Listeners reconstructs the net.Listener values passed to this process by
+// an init system (systemd, launchd, or systemfd) via the LISTEN_FDS/
+// LISTEN_PID environment protocol. Inherited file descriptors start at fd 3
+// and are returned in order.
+//
+// If LISTEN_PID does not match the current process, or LISTEN_FDS is unset
+// or zero, Listeners returns a nil slice and no error so that callers can
+// fall back to binding their own listeners. When sockets are found, the
+// LISTEN_FDS/LISTEN_PID environment variables are unset so that child
+// processes do not also try to claim them.
+func Listeners() ([]net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, nil
+	}
+
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+
+	listeners := make([]net.Listener, 0, count)
+	for i := 0; i < count; i++ {
+		fd := 3 + i
+		file := os.NewFile(uintptr(fd), fmt.Sprintf("listener-%d", i))
+		listener, err := net.FileListener(file)
+		// net.FileListener dup()s the fd internally, so our copy of the file
+		// can always be closed once we're done constructing the listener.
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct listener from fd %d: %w", fd, err)
+		}
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
@@ -0,0 +1,69 @@
+package listenfd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestListeners_NoActivation(t *testing.T) {
+	tests := []struct {
+		name      string
+		listenPID string
+		listenFDs string
+		setPID    bool
+		setFDs    bool
+	}{
+		{name: "no env set"},
+		{name: "LISTEN_PID unset, LISTEN_FDS set", listenFDs: "1", setFDs: true},
+		{name: "LISTEN_PID mismatched", listenPID: "1", setPID: true, listenFDs: "1", setFDs: true},
+		{name: "LISTEN_PID not a number", listenPID: "not-a-number", setPID: true, listenFDs: "1", setFDs: true},
+		{name: "LISTEN_FDS unset", listenPID: fmt.Sprintf("%d", os.Getpid()), setPID: true},
+		{name: "LISTEN_FDS zero", listenPID: fmt.Sprintf("%d", os.Getpid()), setPID: true, listenFDs: "0", setFDs: true},
+		{name: "LISTEN_FDS not a number", listenPID: fmt.Sprintf("%d", os.Getpid()), setPID: true, listenFDs: "not-a-number", setFDs: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Unsetenv("LISTEN_PID")
+			os.Unsetenv("LISTEN_FDS")
+			if tt.setPID {
+				os.Setenv("LISTEN_PID", tt.listenPID)
+			}
+			if tt.setFDs {
+				os.Setenv("LISTEN_FDS", tt.listenFDs)
+			}
+			t.Cleanup(func() {
+				os.Unsetenv("LISTEN_PID")
+				os.Unsetenv("LISTEN_FDS")
+			})
+
+			listeners, err := Listeners()
+			if err != nil {
+				t.Fatalf("Listeners() returned error: %v", err)
+			}
+			if listeners != nil {
+				t.Fatalf("Listeners() = %v, want nil", listeners)
+			}
+		})
+	}
+}
+
+func TestListeners_ZeroFDsKeepsEnv(t *testing.T) {
+	os.Setenv("LISTEN_PID", fmt.Sprintf("%d", os.Getpid()))
+	os.Setenv("LISTEN_FDS", "0")
+	t.Cleanup(func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+	})
+
+	if _, err := Listeners(); err != nil {
+		t.Fatalf("Listeners() returned error: %v", err)
+	}
+
+	// A zero LISTEN_FDS falls back before unsetting anything, so the
+	// environment should be left untouched for the next caller to inspect.
+	if _, ok := os.LookupEnv("LISTEN_PID"); !ok {
+		t.Fatalf("LISTEN_PID was unset on a zero-FD fallback")
+	}
+}
@@ -0,0 +1,76 @@
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const statusFilename = "mirror-status.json"
+
+// Status records the result of the most recent poll of a mirrored
+// upstream. It is persisted alongside the bare repository so that both the
+// mirror daemon ('git-bundle-server mirror') and the web server, which run
+// as separate processes, can observe the same state.
+type Status struct {
+	Route          string    `json:"route"`
+	LastFetchTime  time.Time `json:"last_fetch_time"`
+	LastChangeTime time.Time `json:"last_change_time"`
+	HeadCommit     string    `json:"head_commit"`
+}
+
+func statusPath(repoDir string) string {
+	return filepath.Join(repoDir, statusFilename)
+}
+
+// WriteStatus persists status next to the bare repository at repoDir.
+//
+// It writes to a temporary file in repoDir and renames it into place
+// rather than truncating statusPath directly, so that the web server's
+// /_status handler, which reads this file from a separate process, never
+// observes a partially-written file.
+func WriteStatus(repoDir string, status Status) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mirror status: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(repoDir, statusFilename+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary mirror status file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write mirror status: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write mirror status: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write mirror status: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), statusPath(repoDir)); err != nil {
+		return fmt.Errorf("failed to write mirror status: %w", err)
+	}
+	return nil
+}
+
+// ReadStatus loads the status last written by WriteStatus for the
+// repository at repoDir. ok is false if no status has been recorded yet,
+// e.g. because the repo is not (or not yet) mirrored.
+func ReadStatus(repoDir string) (status Status, ok bool) {
+	data, err := os.ReadFile(statusPath(repoDir))
+	if err != nil {
+		return Status{}, false
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return Status{}, false
+	}
+	return status, true
+}
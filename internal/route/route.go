@@ -0,0 +1,53 @@
+// Package route turns a repository clone URL into the "<owner>/<repo>"
+// route it should be hosted at. The 'init' CLI command uses it when no
+// route is given; the web server's webhook handler does not call it, and
+// takes the route from its own request path instead.
+package route
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// scpLikeURL matches the SCP-style syntax git accepts for SSH remotes,
+// e.g. "git@github.com:foo/bar.git" -- a "[user@]host:path" form with no
+// "://" scheme, which url.Parse rejects outright ("first path segment in
+// URL cannot contain colon").
+var scpLikeURL = regexp.MustCompile(`^[^@/]*@?[^:/]+:(.+)$`)
+
+// FromURL derives a "<owner>/<repo>" route from a clone URL, e.g.
+// "https://github.com/foo/bar.git" -> "foo/bar", or the SCP-style
+// "git@github.com:foo/bar.git" -> "foo/bar".
+func FromURL(rawURL string) (string, error) {
+	path, err := pathFromURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	elements := strings.Split(path, "/")
+	if len(elements) < 2 || elements[len(elements)-1] == "" || elements[len(elements)-2] == "" {
+		return "", fmt.Errorf("could not derive a route from URL '%s'", rawURL)
+	}
+
+	return strings.Join(elements[len(elements)-2:], "/"), nil
+}
+
+// pathFromURL extracts the path portion of a clone URL, understanding both
+// scheme-qualified URLs (https://, ssh://, ...) and the schemeless
+// SCP-style syntax git also accepts for SSH remotes.
+func pathFromURL(rawURL string) (string, error) {
+	if !strings.Contains(rawURL, "://") {
+		if match := scpLikeURL.FindStringSubmatch(rawURL); match != nil {
+			return match[1], nil
+		}
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse URL '%s': %w", rawURL, err)
+	}
+	return parsed.Path, nil
+}
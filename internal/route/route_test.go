@@ -0,0 +1,76 @@
+package route
+
+import "testing"
+
+func TestFromURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawURL  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "https with .git suffix",
+			rawURL: "https://github.com/foo/bar.git",
+			want:   "foo/bar",
+		},
+		{
+			name:   "https without .git suffix",
+			rawURL: "https://github.com/foo/bar",
+			want:   "foo/bar",
+		},
+		{
+			name:   "trailing slash",
+			rawURL: "https://github.com/foo/bar/",
+			want:   "foo/bar",
+		},
+		{
+			name:   "nested path uses last two segments",
+			rawURL: "https://example.com/some/group/foo/bar.git",
+			want:   "foo/bar",
+		},
+		{
+			name:   "scp-style SSH URL",
+			rawURL: "git@github.com:foo/bar.git",
+			want:   "foo/bar",
+		},
+		{
+			name:   "scp-style SSH URL without user",
+			rawURL: "github.com:foo/bar.git",
+			want:   "foo/bar",
+		},
+		{
+			name:    "too few path segments",
+			rawURL:  "https://github.com/bar",
+			wantErr: true,
+		},
+		{
+			name:    "empty path",
+			rawURL:  "https://github.com",
+			wantErr: true,
+		},
+		{
+			name:    "invalid URL",
+			rawURL:  "://not-a-url",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromURL(tt.rawURL)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("FromURL(%q) = %q, nil, want error", tt.rawURL, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromURL(%q) returned error: %v", tt.rawURL, err)
+			}
+			if got != tt.want {
+				t.Errorf("FromURL(%q) = %q, want %q", tt.rawURL, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Package webhook coalesces webhook-triggered bundle regenerations: each
+// route gets a single bounded worker queue so that a burst of rapid pushes
+// to the same repository collapses into one fetch/bundle cycle instead of
+// piling up redundant work.
+package webhook
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// queueCapacity bounds the number of jobs buffered per route. Since a
+// route's worker only ever acts on the latest state of the repo, one
+// buffered slot is enough to coalesce any number of pushes that arrive
+// while a fetch is already running.
+const queueCapacity = 1
+
+// jobRetention is how long a finished job's status stays queryable before
+// it's pruned from the map, so a long-lived server doesn't accumulate an
+// unbounded history of webhook jobs.
+const jobRetention = 10 * time.Minute
+
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobCoalesced JobState = "coalesced"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+)
+
+// Job tracks the status of a single webhook-triggered regeneration.
+type Job struct {
+	ID    string
+	Route string
+	State JobState
+	Error string
+
+	trigger func() error
+}
+
+// Manager dispatches webhook-triggered work to a bounded, per-route worker,
+// and tracks job status for later lookup.
+type Manager struct {
+	nextID  uint64
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	workers map[string]chan *Job
+}
+
+func NewManager() *Manager {
+	return &Manager{
+		jobs:    map[string]*Job{},
+		workers: map[string]chan *Job{},
+	}
+}
+
+// Enqueue schedules trigger to run for route, starting that route's worker
+// goroutine if this is its first job. If a job for the route is already
+// queued, the new job is marked JobCoalesced and folded into it rather than
+// growing the backlog.
+func (m *Manager) Enqueue(route string, trigger func() error) *Job {
+	id := fmt.Sprintf("%d", atomic.AddUint64(&m.nextID, 1))
+	job := &Job{ID: id, Route: route, State: JobQueued, trigger: trigger}
+
+	m.mu.Lock()
+	m.jobs[id] = job
+	queue, ok := m.workers[route]
+	if !ok {
+		queue = make(chan *Job, queueCapacity)
+		m.workers[route] = queue
+		go m.runWorker(queue)
+	}
+	m.mu.Unlock()
+
+	select {
+	case queue <- job:
+	default:
+		job.State = JobCoalesced
+		m.scheduleExpiry(job.ID)
+	}
+
+	return job
+}
+
+func (m *Manager) runWorker(queue chan *Job) {
+	for job := range queue {
+		m.setState(job.ID, JobRunning, nil)
+		if err := job.trigger(); err != nil {
+			m.setState(job.ID, JobFailed, err)
+		} else {
+			m.setState(job.ID, JobSucceeded, nil)
+		}
+		m.scheduleExpiry(job.ID)
+	}
+}
+
+func (m *Manager) setState(id string, state JobState, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return
+	}
+	job.State = state
+	if err != nil {
+		job.Error = err.Error()
+	}
+}
+
+// scheduleExpiry prunes a finished job from m.jobs after jobRetention, so
+// that Status lookups remain possible for a while without the map growing
+// unbounded over the life of the process.
+func (m *Manager) scheduleExpiry(id string) {
+	time.AfterFunc(jobRetention, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.jobs, id)
+	})
+}
+
+// Status returns the job with the given id, if any.
+func (m *Manager) Status(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
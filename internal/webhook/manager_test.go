@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const testTimeout = 2 * time.Second
+
+func waitForState(t *testing.T, m *Manager, id string, want JobState) Job {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for time.Now().Before(deadline) {
+		job, ok := m.Status(id)
+		if !ok {
+			t.Fatalf("Status(%q) not found", id)
+		}
+		if job.State == want {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %q did not reach state %q in time", id, want)
+	return Job{}
+}
+
+func TestManager_EachJobRunsItsOwnTrigger(t *testing.T) {
+	m := NewManager()
+
+	first := make(chan struct{})
+	release := make(chan struct{})
+	var firstRan, secondRan bool
+
+	job1 := m.Enqueue("owner/repo", func() error {
+		firstRan = true
+		close(first)
+		<-release
+		return nil
+	})
+
+	<-first // wait until the first job is actually running before enqueuing the second
+
+	job2 := m.Enqueue("owner/repo", func() error {
+		secondRan = true
+		return nil
+	})
+
+	close(release)
+
+	waitForState(t, m, job1.ID, JobSucceeded)
+	waitForState(t, m, job2.ID, JobSucceeded)
+
+	if !firstRan {
+		t.Errorf("first job's trigger never ran")
+	}
+	if !secondRan {
+		t.Errorf("second job's trigger never ran; runWorker likely re-invoked the first job's closure")
+	}
+}
+
+func TestManager_CoalescesBurstsPerRoute(t *testing.T) {
+	m := NewManager()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	job1 := m.Enqueue("owner/repo", func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	// The worker is now blocked processing job1, so a second and third job
+	// queued for the same route should collapse into one coalesced job
+	// rather than growing the backlog.
+	job2 := m.Enqueue("owner/repo", func() error { return nil })
+	job3 := m.Enqueue("owner/repo", func() error { return nil })
+
+	// Read states before unblocking job1: job2 fit in the one buffered slot
+	// and is waiting behind it, while job3 found the slot already taken and
+	// was coalesced into job2 rather than queued.
+	job2State := job2.State
+	job3State := job3.State
+
+	close(release)
+	waitForState(t, m, job1.ID, JobSucceeded)
+
+	if job2State != JobQueued {
+		t.Errorf("job2.State = %s, want %s", job2State, JobQueued)
+	}
+	if job3State != JobCoalesced {
+		t.Errorf("job3.State = %s, want %s", job3State, JobCoalesced)
+	}
+}
+
+func TestManager_StatusReportsFailure(t *testing.T) {
+	m := NewManager()
+
+	wantErr := errors.New("boom")
+	job := m.Enqueue("owner/repo", func() error { return wantErr })
+
+	final := waitForState(t, m, job.ID, JobFailed)
+	if final.Error != wantErr.Error() {
+		t.Errorf("Error = %q, want %q", final.Error, wantErr.Error())
+	}
+}
+
+func TestManager_StatusUnknownID(t *testing.T) {
+	m := NewManager()
+	if _, ok := m.Status("does-not-exist"); ok {
+		t.Errorf("Status() for unknown id returned ok=true")
+	}
+}